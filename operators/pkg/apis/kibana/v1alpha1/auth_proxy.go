@@ -0,0 +1,32 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KibanaAuthProxy configures an OAuth proxy sidecar (e.g. oauth2-proxy or
+// openshift/oauth-proxy) deployed alongside Kibana to authenticate requests
+// against an external OIDC provider before they reach Kibana.
+type KibanaAuthProxy struct {
+	// Image is the oauth proxy image to run as a sidecar.
+	Image string `json:"image,omitempty"`
+	// ProviderURL is the OIDC issuer URL used to authenticate users.
+	ProviderURL string `json:"providerURL"`
+	// ClientIDSecretKeyRef references the OAuth client ID.
+	ClientIDSecretKeyRef corev1.SecretKeySelector `json:"clientIDSecretKeyRef"`
+	// ClientSecretSecretKeyRef references the OAuth client secret.
+	ClientSecretSecretKeyRef corev1.SecretKeySelector `json:"clientSecretSecretKeyRef"`
+	// AllowedEmailDomains restricts sign-in to these email domains. Empty allows any domain.
+	AllowedEmailDomains []string `json:"allowedEmailDomains,omitempty"`
+	// CookieExpire sets how long the session cookie issued by the proxy stays valid.
+	CookieExpire metav1.Duration `json:"cookieExpire,omitempty"`
+	// CookieRefresh sets how often the proxy refreshes the session cookie.
+	CookieRefresh metav1.Duration `json:"cookieRefresh,omitempty"`
+	// SkipAuthRegexes lists upstream paths the proxy forwards without requiring authentication.
+	SkipAuthRegexes []string `json:"skipAuthRegexes,omitempty"`
+}
@@ -0,0 +1,14 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+// KibanaMonitoring configures the kibana-prometheus-exporter sidecar that exposes Kibana metrics
+// for scraping by Prometheus.
+type KibanaMonitoring struct {
+	// Enabled controls whether the metrics exporter sidecar is injected.
+	Enabled bool `json:"enabled,omitempty"`
+	// Image is the kibana-prometheus-exporter image to run as a sidecar.
+	Image string `json:"image,omitempty"`
+}
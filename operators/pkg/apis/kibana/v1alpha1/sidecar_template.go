@@ -0,0 +1,39 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KibanaSidecarTemplate is a cluster-scoped resource declaring sidecar containers that the pod
+// admission webhook injects into matching Kibana pods, without requiring changes to KibanaSpec.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type KibanaSidecarTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KibanaSidecarTemplateSpec `json:"spec,omitempty"`
+}
+
+// KibanaSidecarTemplateSpec is the specification of a KibanaSidecarTemplate.
+type KibanaSidecarTemplateSpec struct {
+	// Selector restricts which Kibana pods this template applies to, in addition to the implicit
+	// Kibana controller label selector.
+	Selector metav1.LabelSelector `json:"selector,omitempty"`
+	// Containers are appended to the pod spec as additional sidecars.
+	Containers []corev1.Container `json:"containers,omitempty"`
+	// Volumes are appended to the pod spec to back the sidecar containers.
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+}
+
+// KibanaSidecarTemplateList contains a list of KibanaSidecarTemplate.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type KibanaSidecarTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KibanaSidecarTemplate `json:"items"`
+}
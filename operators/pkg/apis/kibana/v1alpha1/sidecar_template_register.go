@@ -0,0 +1,12 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+// Registers KibanaSidecarTemplate/KibanaSidecarTemplateList with this package's SchemeBuilder
+// (declared alongside the other Kibana API types) so client.List can decode them as
+// runtime.Object, e.g. from the pod admission webhook.
+func init() {
+	SchemeBuilder.Register(&KibanaSidecarTemplate{}, &KibanaSidecarTemplateList{})
+}
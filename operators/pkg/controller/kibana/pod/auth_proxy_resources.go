@@ -0,0 +1,142 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// tlsCertValidity is how long the auth proxy's self-signed serving certificate is valid for.
+const tlsCertValidity = 365 * 24 * time.Hour
+
+// NewAuthProxyCookieSecret generates a random cookie secret for the Kibana resource named
+// kibanaName and wraps it in the Secret the auth proxy sidecar reads via
+// OAUTH2_PROXY_COOKIE_SECRET.
+func NewAuthProxyCookieSecret(namespace, kibanaName string) (corev1.Secret, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return corev1.Secret{}, fmt.Errorf("while generating auth proxy cookie secret: %w", err)
+	}
+
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cookieSecretName(kibanaName),
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			cookieSecretFileName: []byte(base64.URLEncoding.EncodeToString(raw)),
+		},
+	}, nil
+}
+
+// NewAuthProxyServingCertSecret generates a self-signed serving certificate for dnsName and wraps
+// it in the Secret the auth proxy sidecar for the Kibana resource named kibanaName mounts at
+// tlsMountPath to terminate TLS on AuthProxyPort.
+func NewAuthProxyServingCertSecret(namespace, kibanaName, dnsName string) (corev1.Secret, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return corev1.Secret{}, fmt.Errorf("while generating auth proxy serving cert key: %w", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return corev1.Secret{}, fmt.Errorf("while generating auth proxy serving cert serial number: %w", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: dnsName},
+		DNSNames:              []string{dnsName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(tlsCertValidity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return corev1.Secret{}, fmt.Errorf("while creating auth proxy serving cert: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tlsSecretName(kibanaName),
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			tlsCertFileName: certPEM,
+			tlsKeyFileName:  keyPEM,
+		},
+	}, nil
+}
+
+// NewAuthProxyRBAC builds the ServiceAccount and RBAC binding the auth proxy sidecar for the
+// Kibana resource named kibanaName needs to perform TokenReview/SubjectAccessReview calls when
+// validating bearer tokens. The ClusterRole and ClusterRoleBinding are cluster-scoped, so their
+// names are additionally qualified with namespace to avoid colliding with another namespace's
+// Kibana resource of the same name.
+func NewAuthProxyRBAC(namespace, kibanaName string) (corev1.ServiceAccount, rbacv1.ClusterRole, rbacv1.ClusterRoleBinding) {
+	serviceAccountName := authProxyServiceAccountName(kibanaName)
+	clusterScopedName := fmt.Sprintf("%s-%s", namespace, serviceAccountName)
+
+	serviceAccount := corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceAccountName,
+			Namespace: namespace,
+		},
+	}
+
+	clusterRole := rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterScopedName,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"authentication.k8s.io"},
+				Resources: []string{"tokenreviews"},
+				Verbs:     []string{"create"},
+			},
+			{
+				APIGroups: []string{"authorization.k8s.io"},
+				Resources: []string{"subjectaccessreviews"},
+				Verbs:     []string{"create"},
+			},
+		},
+	}
+
+	clusterRoleBinding := rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterScopedName,
+		},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.ServiceAccountKind, Name: serviceAccount.Name, Namespace: namespace},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole.Name,
+		},
+	}
+
+	return serviceAccount, clusterRole, clusterRoleBinding
+}
@@ -0,0 +1,120 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/cloud-on-k8s/operators/pkg/apis/kibana/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DebugAnnotation, when set to "true" on a Kibana pod, requests a debug container be
+	// attached through EnsureDebugContainer. Older clusters without the ephemeralcontainers
+	// subresource ignore it.
+	DebugAnnotation = "elastic.co/debug"
+	// debugContainerName is the name given to the ephemeral debug container.
+	debugContainerName = "debug"
+	// defaultDebugImage bundles curl, the Elasticsearch client and netcat for troubleshooting.
+	defaultDebugImage = "docker.elastic.co/eck/eck-debug-tools"
+)
+
+// DebugContainerStatus reports the outcome of a debug container attached via EnsureDebugContainer.
+// This snapshot of the repository doesn't carry the Kibana CRD status type, so there is nowhere
+// to add a DebugContainerStatus field yet; callers that do have one should copy the result of
+// DebugContainerStatusFor onto it during reconciliation.
+type DebugContainerStatus struct {
+	Image     string       `json:"image,omitempty"`
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	ExitCode  *int32       `json:"exitCode,omitempty"`
+}
+
+// EnsureDebugContainer attaches an ephemeral debug container to pod, sharing its process
+// namespace and the same Elasticsearch connection details and credentials Kibana uses, so
+// operators can run diagnostics against ES without kubectl cp/exec hacks. It is a no-op if a
+// debug container is already present. ephemeralContainersSupported gates this on clusters where
+// the ephemeralcontainers subresource is not available (behind the EphemeralContainers feature
+// flag before Kubernetes 1.23); callers should resolve it once via API discovery rather than per
+// reconciliation.
+func EnsureDebugContainer(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, elasticsearchURL string, auth v1alpha1.ElasticsearchAuth, ephemeralContainersSupported bool) error {
+	if !ephemeralContainersSupported {
+		return fmt.Errorf("cannot attach debug container to pod %s/%s: ephemeralcontainers subresource not supported by this cluster", pod.Namespace, pod.Name)
+	}
+
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == debugContainerName {
+			return nil
+		}
+	}
+
+	env := ApplyToEnv(auth, []corev1.EnvVar{
+		{Name: "ELASTICSEARCH_URL", Value: elasticsearchURL},
+	})
+
+	// ShareProcessNamespace is immutable on a running pod and isn't part of the ephemeralcontainers
+	// subresource, so it can't be flipped on here: PID namespace sharing with the debug container
+	// only works if the Kibana pod already had it set at creation time. TargetContainerName still
+	// scopes the debug container's network/filesystem namespaces to the Kibana container.
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:  debugContainerName,
+			Image: defaultDebugImage,
+			Env:   env,
+			Stdin: true,
+			TTY:   true,
+		},
+		TargetContainerName: v1alpha1.KibanaContainerName,
+	})
+
+	// UpdateEphemeralContainers takes ctx as its first argument, which client-go only started doing
+	// in 1.18; it also only exists at all from the release that added the ephemeralcontainers
+	// subresource client (1.16+, alongside the EphemeralContainers feature gate). Pin client-go to
+	// at least that version in go.mod before this package is wired into the build.
+	_, err := clientset.CoreV1().Pods(pod.Namespace).UpdateEphemeralContainers(ctx, pod.Name, pod, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("while attaching debug container to pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// DebugRequested returns whether pod has been annotated to request a debug container.
+func DebugRequested(pod *corev1.Pod) bool {
+	return debugRequested(pod.Annotations)
+}
+
+// debugRequested returns whether annotations carries the DebugAnnotation requesting a debug
+// container. NewSpec uses it directly against the PodTemplate's annotations so it can set
+// ShareProcessNamespace at pod creation time, since it can no longer be flipped on once the pod
+// is running (see EnsureDebugContainer).
+func debugRequested(annotations map[string]string) bool {
+	return annotations[DebugAnnotation] == "true"
+}
+
+// DebugContainerStatusFor reads back the debug container's state from pod.Status, for the
+// reconciler to surface onto the Kibana resource status. Returns nil if no debug container has
+// been attached yet, or the kubelet hasn't reported its status.
+func DebugContainerStatusFor(pod *corev1.Pod) *DebugContainerStatus {
+	for _, cs := range pod.Status.EphemeralContainerStatuses {
+		if cs.Name != debugContainerName {
+			continue
+		}
+		status := &DebugContainerStatus{Image: cs.Image}
+		switch {
+		case cs.State.Running != nil:
+			status.StartedAt = &cs.State.Running.StartedAt
+		case cs.State.Terminated != nil:
+			status.StartedAt = &cs.State.Terminated.StartedAt
+			exitCode := cs.State.Terminated.ExitCode
+			status.ExitCode = &exitCode
+		}
+		return status
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDebugRequested(t *testing.T) {
+	requested := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DebugAnnotation: "true"}},
+	}
+	assert.True(t, DebugRequested(requested))
+
+	notRequested := &corev1.Pod{}
+	assert.False(t, DebugRequested(notRequested))
+}
+
+func TestDebugContainerStatusFor(t *testing.T) {
+	assert.Nil(t, DebugContainerStatusFor(&corev1.Pod{}))
+
+	startedAt := metav1.Now()
+	running := &corev1.Pod{
+		Status: corev1.PodStatus{
+			EphemeralContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  debugContainerName,
+					Image: defaultDebugImage,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: startedAt}},
+				},
+			},
+		},
+	}
+	status := DebugContainerStatusFor(running)
+	if assert.NotNil(t, status) {
+		assert.Equal(t, defaultDebugImage, status.Image)
+		assert.Equal(t, startedAt, *status.StartedAt)
+		assert.Nil(t, status.ExitCode)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// metricsServiceNameSuffix is appended to the Kibana resource name to name its metrics Service.
+const metricsServiceNameSuffix = "-metrics"
+
+// NewMetricsService returns a headless Service exposing MetricsPort on the Kibana pods selected
+// by selector, for Prometheus (or a ServiceMonitor, see the prometheus_servicemonitor build tag)
+// to scrape. Only meaningful when Monitoring is enabled; callers should skip creating it otherwise.
+func NewMetricsService(namespace, kibanaName string, selector map[string]string) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kibanaName + metricsServiceNameSuffix,
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  selector,
+			Ports: []corev1.ServicePort{
+				{Name: "metrics", Port: int32(MetricsPort), TargetPort: intstr.FromInt(MetricsPort)},
+			},
+		},
+	}
+}
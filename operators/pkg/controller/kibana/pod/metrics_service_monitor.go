@@ -0,0 +1,30 @@
+// +build prometheus_servicemonitor
+
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewServiceMonitor returns a ServiceMonitor targeting the metrics Service returned by
+// NewMetricsService. Only built when the prometheus_servicemonitor build tag is set, since it
+// depends on the Prometheus Operator CRDs being installed in the cluster; the controller should
+// only reconcile one when it detects that CRD is present.
+func NewServiceMonitor(namespace, kibanaName string, selector map[string]string) monitoringv1.ServiceMonitor {
+	return monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kibanaName + metricsServiceNameSuffix,
+			Namespace: namespace,
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Selector:  metav1.LabelSelector{MatchLabels: selector},
+			Endpoints: []monitoringv1.Endpoint{{Port: "metrics"}},
+		},
+	}
+}
@@ -5,6 +5,9 @@
 package pod
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/elastic/cloud-on-k8s/operators/pkg/apis/kibana/v1alpha1"
 	"github.com/elastic/cloud-on-k8s/operators/pkg/utils/stringsutil"
 
@@ -15,12 +18,53 @@ import (
 
 const (
 	// HTTPPort is the (default) port used by Kibana
-	HTTPPort                             = 5601
-	elasticsearchUsername                = "ELASTICSEARCH_USERNAME"
-	elasticsearchPassword                = "ELASTICSEARCH_PASSWORD"
+	HTTPPort = 5601
+	// AuthProxyPort is the port the auth proxy sidecar listens on when AuthProxy is configured.
+	AuthProxyPort = 4180
+	// MetricsPort is the port the Prometheus exporter sidecar listens on when Monitoring is enabled.
+	MetricsPort                   = 9684
+	elasticsearchUsername         = "ELASTICSEARCH_USERNAME"
+	elasticsearchPassword         = "ELASTICSEARCH_PASSWORD"
 	defaultImageRepositoryAndName string = "docker.elastic.co/kibana/kibana"
+	defaultAuthProxyImage         string = "quay.io/oauth2-proxy/oauth2-proxy"
+	authProxyContainerName        string = "auth-proxy"
+	authProxyServiceAccountSuffix string = "-auth-proxy"
+	cookieSecretSuffix            string = "-auth-proxy-cookie"
+	cookieSecretFileName          string = "cookie-secret"
+	tlsSecretSuffix               string = "-auth-proxy-tls"
+	tlsMountPath                  string = "/mnt/auth-proxy-tls"
+	tlsCertFileName               string = "tls.crt"
+	tlsKeyFileName                string = "tls.key"
+	registryAuthSecretSuffix      string = "-registry-auth"
+	defaultMetricsExporterImage   string = "docker.elastic.co/observability-ci/kibana-prometheus-exporter"
+	metricsExporterContainerName  string = "metrics-exporter"
 )
 
+// authProxyServiceAccountName returns the namespace-local ServiceAccount name for the auth
+// proxy sidecar of the Kibana resource named kibanaName, so multiple Kibana instances in the
+// same namespace don't collide on a shared identity.
+func authProxyServiceAccountName(kibanaName string) string {
+	return kibanaName + authProxyServiceAccountSuffix
+}
+
+// cookieSecretName returns the namespace-local Secret name holding the auth proxy cookie secret
+// for the Kibana resource named kibanaName.
+func cookieSecretName(kibanaName string) string {
+	return kibanaName + cookieSecretSuffix
+}
+
+// tlsSecretName returns the namespace-local Secret name holding the auth proxy's self-signed
+// serving certificate for the Kibana resource named kibanaName.
+func tlsSecretName(kibanaName string) string {
+	return kibanaName + tlsSecretSuffix
+}
+
+// registryAuthSecretName returns the namespace-local docker-registry Secret name generated from
+// SpecParams.RegistryAuth for the Kibana resource named kibanaName.
+func registryAuthSecretName(kibanaName string) string {
+	return kibanaName + registryAuthSecretSuffix
+}
+
 // DefaultResources are resource limits to apply to Kibana container by default
 var DefaultResources = corev1.ResourceRequirements{
 	Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
@@ -47,11 +91,28 @@ func ApplyToEnv(auth v1alpha1.ElasticsearchAuth, env []corev1.EnvVar) []corev1.E
 }
 
 type SpecParams struct {
+	// Name is the name of the Kibana resource this pod belongs to. It namespaces the generated
+	// Secret/ServiceAccount names used by AuthProxy and RegistryAuth, so multiple Kibana
+	// instances in the same namespace don't collide on a shared identity.
+	Name             string
 	Version          string
 	ElasticsearchUrl string
 	CustomImageName  string
 	User             v1alpha1.ElasticsearchAuth
 	PodTemplate      corev1.PodTemplateSpec
+	// ImagePullSecrets are added to the Kibana pod spec in addition to any
+	// already set on PodTemplate.Spec.ImagePullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference
+	// RegistryAuth, when set, auto-creates a docker-registry Secret (named via
+	// registryAuthSecretName) and references it from ImagePullSecrets.
+	RegistryAuth *RegistryAuth
+	// AuthProxy, when set, fronts Kibana with an OAuth proxy sidecar that terminates TLS on
+	// AuthProxyPort using a self-signed serving certificate, and authenticates requests against
+	// an external OIDC provider before forwarding to Kibana.
+	AuthProxy *v1alpha1.KibanaAuthProxy
+	// Monitoring, when enabled, injects a kibana-prometheus-exporter sidecar exposing Kibana
+	// metrics on MetricsPort.
+	Monitoring v1alpha1.KibanaMonitoring
 }
 
 func imageWithVersion(image string, version string) string {
@@ -81,21 +142,154 @@ func NewSpec(p SpecParams, env EnvFactory) corev1.PodSpec {
 		},
 	}
 
-	automountServiceAccountToken := false
+	automountServiceAccountToken := p.AuthProxy != nil
+	serviceAccountName := p.PodTemplate.Spec.ServiceAccountName
+	if p.AuthProxy != nil && serviceAccountName == "" {
+		serviceAccountName = authProxyServiceAccountName(p.Name)
+	}
+
+	// ShareProcessNamespace must be set at pod creation time: it's immutable afterwards and isn't
+	// part of the ephemeralcontainers subresource, so a debug container attached later via
+	// EnsureDebugContainer can only share the Kibana container's PID namespace if this was already
+	// turned on here.
+	shareProcessNamespace := debugRequested(p.PodTemplate.Annotations)
+
+	containers := []corev1.Container{{
+		Resources: resourceRequirements(p.PodTemplate),
+		Env:       env(p),
+		Image:     imageName,
+		Name:      v1alpha1.KibanaContainerName,
+		Ports: []corev1.ContainerPort{
+			{Name: "http", ContainerPort: int32(HTTPPort), Protocol: corev1.ProtocolTCP},
+		},
+		ReadinessProbe: probe,
+	}}
+
+	var volumes []corev1.Volume
+	if p.AuthProxy != nil {
+		containers = append(containers, authProxyContainer(*p.AuthProxy, p.Name))
+		volumes = append(volumes, tlsVolume(p.Name))
+	}
+	if p.Monitoring.Enabled {
+		containers = append(containers, metricsExporterContainer(p))
+	}
 
 	return corev1.PodSpec{
-		Affinity: p.PodTemplate.Spec.Affinity,
-		Containers: []corev1.Container{{
-			Resources: resourceRequirements(p.PodTemplate),
-			Env:       env(p),
-			Image:     imageName,
-			Name:      v1alpha1.KibanaContainerName,
-			Ports: []corev1.ContainerPort{
-				{Name: "http", ContainerPort: int32(HTTPPort), Protocol: corev1.ProtocolTCP},
-			},
-			ReadinessProbe: probe,
-		}},
+		Affinity:                     p.PodTemplate.Spec.Affinity,
+		Containers:                   containers,
+		Volumes:                      volumes,
+		ImagePullSecrets:             imagePullSecrets(p),
+		ServiceAccountName:           serviceAccountName,
 		AutomountServiceAccountToken: &automountServiceAccountToken,
+		ShareProcessNamespace:        &shareProcessNamespace,
+	}
+}
+
+// authProxyContainer builds the oauth2-proxy sidecar that terminates TLS on AuthProxyPort using a
+// self-signed serving certificate (see NewAuthProxyServingCertSecret), authenticates requests
+// against auth.ProviderURL via the oidc provider, and forwards authenticated traffic to Kibana
+// over localhost.
+func authProxyContainer(auth v1alpha1.KibanaAuthProxy, kibanaName string) corev1.Container {
+	image := auth.Image
+	if image == "" {
+		image = defaultAuthProxyImage
+	}
+
+	emailDomains := "*"
+	if len(auth.AllowedEmailDomains) > 0 {
+		emailDomains = strings.Join(auth.AllowedEmailDomains, ",")
+	}
+
+	args := []string{
+		"--provider=oidc",
+		fmt.Sprintf("--https-address=0.0.0.0:%d", AuthProxyPort),
+		fmt.Sprintf("--tls-cert-file=%s/%s", tlsMountPath, tlsCertFileName),
+		fmt.Sprintf("--tls-key-file=%s/%s", tlsMountPath, tlsKeyFileName),
+		fmt.Sprintf("--upstream=http://localhost:%d", HTTPPort),
+		fmt.Sprintf("--oidc-issuer-url=%s", auth.ProviderURL),
+	}
+	if auth.CookieExpire.Duration != 0 {
+		args = append(args, fmt.Sprintf("--cookie-expire=%s", auth.CookieExpire.Duration))
+	}
+	if auth.CookieRefresh.Duration != 0 {
+		args = append(args, fmt.Sprintf("--cookie-refresh=%s", auth.CookieRefresh.Duration))
+	}
+	for _, regex := range auth.SkipAuthRegexes {
+		args = append(args, fmt.Sprintf("--skip-auth-regex=%s", regex))
+	}
+
+	return corev1.Container{
+		Name:  authProxyContainerName,
+		Image: image,
+		Args:  args,
+		Env: []corev1.EnvVar{
+			{Name: "OAUTH2_PROXY_CLIENT_ID", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &auth.ClientIDSecretKeyRef}},
+			{Name: "OAUTH2_PROXY_CLIENT_SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &auth.ClientSecretSecretKeyRef}},
+			{Name: "OAUTH2_PROXY_EMAIL_DOMAINS", Value: emailDomains},
+			{Name: "OAUTH2_PROXY_COOKIE_SECRET", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cookieSecretName(kibanaName)},
+					Key:                  cookieSecretFileName,
+				},
+			}},
+		},
+		Ports: []corev1.ContainerPort{
+			{Name: "auth-proxy", ContainerPort: int32(AuthProxyPort), Protocol: corev1.ProtocolTCP},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: tlsSecretName(kibanaName), MountPath: tlsMountPath, ReadOnly: true},
+		},
+		ReadinessProbe: &corev1.Probe{
+			FailureThreshold:    3,
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       10,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      5,
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Port:   intstr.FromInt(AuthProxyPort),
+					Path:   "/ping",
+					Scheme: corev1.URISchemeHTTPS,
+				},
+			},
+		},
+	}
+}
+
+// metricsExporterContainer builds the kibana-prometheus-exporter sidecar, wiring it to the same
+// Elasticsearch connection details and credentials as the Kibana container so Prometheus can
+// scrape Kibana-reported Elasticsearch metrics on MetricsPort.
+func metricsExporterContainer(p SpecParams) corev1.Container {
+	image := p.Monitoring.Image
+	if image == "" {
+		image = defaultMetricsExporterImage
+	}
+
+	env := ApplyToEnv(p.User, []corev1.EnvVar{
+		{Name: "ELASTICSEARCH_URL", Value: p.ElasticsearchUrl},
+	})
+
+	return corev1.Container{
+		Name:  metricsExporterContainerName,
+		Image: image,
+		Env:   env,
+		Ports: []corev1.ContainerPort{
+			{Name: "metrics", ContainerPort: int32(MetricsPort), Protocol: corev1.ProtocolTCP},
+		},
+	}
+}
+
+// tlsVolume mounts the self-signed serving certificate (see NewAuthProxyServingCertSecret) the
+// auth proxy sidecar uses to terminate TLS on AuthProxyPort.
+func tlsVolume(kibanaName string) corev1.Volume {
+	name := tlsSecretName(kibanaName)
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: name,
+			},
+		},
 	}
 }
 
@@ -109,3 +303,15 @@ func resourceRequirements(podTemplate corev1.PodTemplateSpec) corev1.ResourceReq
 	}
 	return DefaultResources
 }
+
+// imagePullSecrets merges the pull secrets set directly on SpecParams, the reference to the
+// Secret generated from RegistryAuth (if any), and any declared on the user-provided PodTemplate,
+// so all three ways of referencing registry credentials are honored.
+func imagePullSecrets(p SpecParams) []corev1.LocalObjectReference {
+	secrets := append([]corev1.LocalObjectReference{}, p.ImagePullSecrets...)
+	if p.RegistryAuth != nil {
+		secrets = append(secrets, corev1.LocalObjectReference{Name: registryAuthSecretName(p.Name)})
+	}
+	secrets = append(secrets, p.PodTemplate.Spec.ImagePullSecrets...)
+	return secrets
+}
@@ -0,0 +1,67 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RegistryAuth describes credentials for a single private image registry, to be turned into a
+// docker-registry Secret so users don't have to pre-create one themselves.
+type RegistryAuth struct {
+	Server   string
+	Username string
+	Password string
+	Email    string
+}
+
+// dockerConfigJSON mirrors the on-disk layout Kubernetes expects in a
+// corev1.SecretTypeDockerConfigJson secret (see kubernetes.io/dockerconfigjson).
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+	Auth     string `json:"auth"`
+}
+
+// SecretFor builds a docker-registry Secret for auth, named name in namespace, that can be
+// referenced from SpecParams.ImagePullSecrets.
+func (auth RegistryAuth) SecretFor(namespace, name string) (corev1.Secret, error) {
+	config := dockerConfigJSON{
+		Auths: map[string]dockerConfigEntry{
+			auth.Server: {
+				Username: auth.Username,
+				Password: auth.Password,
+				Email:    auth.Email,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", auth.Username, auth.Password))),
+			},
+		},
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return corev1.Secret{}, err
+	}
+
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: configJSON,
+		},
+	}, nil
+}
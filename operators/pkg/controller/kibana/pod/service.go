@@ -0,0 +1,36 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NewService returns the Service fronting the Kibana pods selected by selector. When authProxy is
+// set, the Service targets AuthProxyPort so all traffic is forced through the OIDC-authenticating
+// sidecar instead of reaching the Kibana container directly.
+func NewService(namespace, kibanaName string, selector map[string]string, authProxy bool) corev1.Service {
+	port := int32(HTTPPort)
+	scheme := "http"
+	if authProxy {
+		port = int32(AuthProxyPort)
+		scheme = "https"
+	}
+
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kibanaName,
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Ports: []corev1.ServicePort{
+				{Name: scheme, Port: port, TargetPort: intstr.FromInt(int(port))},
+			},
+		},
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package pod implements a mutating admission webhook that customizes Kibana pods at admission
+// time, as an alternative to baking every customization into pod.NewSpec. It lets users attach
+// extra env vars, secret volumes, and sidecar containers to Kibana pods without forking the
+// operator.
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elastic/cloud-on-k8s/operators/pkg/apis/kibana/v1alpha1"
+	"github.com/elastic/cloud-on-k8s/operators/pkg/controller/elasticsearch/volume"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// EnvFromAnnotation lists ConfigMaps/Secrets (as "configMap:name" or "secret:name", comma
+	// separated) whose keys are injected as env vars into the Kibana container.
+	EnvFromAnnotation = "kibana.k8s.elastic.co/env-from"
+	// SecretVolumesAnnotation holds a JSON-encoded []SecretVolumeRequest describing additional
+	// SelectiveSecretVolume mounts to add to the Kibana pod.
+	SecretVolumesAnnotation = "kibana.k8s.elastic.co/secret-volumes"
+
+	kibanaTypeLabelName  = "common.k8s.elastic.co/type"
+	kibanaTypeLabelValue = "kibana"
+)
+
+// SecretVolumeRequest describes a single secret volume to mount into the Kibana container, as
+// declared through the SecretVolumesAnnotation.
+type SecretVolumeRequest struct {
+	SecretName string   `json:"secretName"`
+	VolumeName string   `json:"volumeName"`
+	MountPath  string   `json:"mountPath"`
+	Items      []string `json:"items,omitempty"`
+}
+
+// PodMutator mutates Kibana pods at admission time based on annotations and cluster-scoped
+// KibanaSidecarTemplate resources, so users can customize pods without changing KibanaSpec.
+type PodMutator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+// Handle implements admission.Handler.
+func (m *PodMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := m.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if !isKibanaPod(pod) {
+		return admission.Allowed("not a kibana pod")
+	}
+
+	mutated := pod.DeepCopy()
+
+	if err := injectEnvFrom(mutated); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := injectSecretVolumes(mutated); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := m.injectSidecarTemplates(ctx, mutated); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	marshaled, err := json.Marshal(mutated)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// InjectDecoder injects the decoder, satisfying admission.DecoderInjector.
+func (m *PodMutator) InjectDecoder(d *admission.Decoder) error {
+	m.decoder = d
+	return nil
+}
+
+func isKibanaPod(pod *corev1.Pod) bool {
+	return pod.Labels[kibanaTypeLabelName] == kibanaTypeLabelValue
+}
+
+func kibanaContainer(pod *corev1.Pod) *corev1.Container {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == v1alpha1.KibanaContainerName {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+// injectEnvFrom appends EnvFromSource entries to the Kibana container for every ConfigMap/Secret
+// referenced in EnvFromAnnotation.
+func injectEnvFrom(pod *corev1.Pod) error {
+	raw, ok := pod.Annotations[EnvFromAnnotation]
+	if !ok || raw == "" {
+		return nil
+	}
+	container := kibanaContainer(pod)
+	if container == nil {
+		return nil
+	}
+	for _, ref := range strings.Split(raw, ",") {
+		kind, name, err := parseEnvFromRef(strings.TrimSpace(ref))
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case "configMap":
+			container.EnvFrom = append(container.EnvFrom, corev1.EnvFromSource{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+			})
+		case "secret":
+			container.EnvFrom = append(container.EnvFrom, corev1.EnvFromSource{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+			})
+		default:
+			return fmt.Errorf("invalid kind %q for annotation %s, expected configMap or secret", kind, EnvFromAnnotation)
+		}
+	}
+	return nil
+}
+
+func parseEnvFromRef(ref string) (kind string, name string, err error) {
+	parts := strings.SplitN(ref, ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid value %q for annotation %s, expected configMap:name or secret:name", ref, EnvFromAnnotation)
+	}
+	return parts[0], parts[1], nil
+}
+
+// injectSecretVolumes adds a SelectiveSecretVolume (and its mount) to the Kibana container for
+// every entry declared in SecretVolumesAnnotation.
+func injectSecretVolumes(pod *corev1.Pod) error {
+	raw, ok := pod.Annotations[SecretVolumesAnnotation]
+	if !ok || raw == "" {
+		return nil
+	}
+	var requests []SecretVolumeRequest
+	if err := json.Unmarshal([]byte(raw), &requests); err != nil {
+		return err
+	}
+	container := kibanaContainer(pod)
+	if container == nil {
+		return nil
+	}
+	for _, r := range requests {
+		secretVolume := volume.NewSelectiveSecretVolumeWithMountPath(r.SecretName, r.VolumeName, r.MountPath, r.Items)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, secretVolume.Volume())
+		container.VolumeMounts = append(container.VolumeMounts, secretVolume.VolumeMount())
+	}
+	return nil
+}
+
+// injectSidecarTemplates appends containers and volumes declared on any KibanaSidecarTemplate
+// whose selector matches the pod's labels.
+func (m *PodMutator) injectSidecarTemplates(ctx context.Context, pod *corev1.Pod) error {
+	var templates v1alpha1.KibanaSidecarTemplateList
+	if err := m.Client.List(ctx, &templates); err != nil {
+		return err
+	}
+	for _, tpl := range templates.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&tpl.Spec.Selector)
+		if err != nil {
+			return err
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, tpl.Spec.Containers...)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, tpl.Spec.Volumes...)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/elastic/cloud-on-k8s/operators/pkg/apis/kibana/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseEnvFromRef(t *testing.T) {
+	kind, name, err := parseEnvFromRef("configMap:my-config")
+	require.NoError(t, err)
+	assert.Equal(t, "configMap", kind)
+	assert.Equal(t, "my-config", name)
+
+	_, _, err = parseEnvFromRef("my-config")
+	assert.Error(t, err)
+}
+
+func TestIsKibanaPod(t *testing.T) {
+	kibanaPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{kibanaTypeLabelName: kibanaTypeLabelValue}},
+	}
+	assert.True(t, isKibanaPod(kibanaPod))
+
+	otherPod := &corev1.Pod{}
+	assert.False(t, isKibanaPod(otherPod))
+}
+
+func TestInjectEnvFromRejectsUnknownKind(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{EnvFromAnnotation: "configmap:my-config"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: v1alpha1.KibanaContainerName}}},
+	}
+
+	err := injectEnvFrom(pod)
+	assert.Error(t, err)
+}